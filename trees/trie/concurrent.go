@@ -0,0 +1,325 @@
+package trie
+
+import (
+	"errors"
+	"sync"
+)
+
+// ConcurrentTrie is an opt-in thread-safe wrapper around a Trie. Writes are
+// serialized with a mutex; each write copies only the nodes on the path it
+// touches (copy-on-write), so a Snapshot taken before the write keeps
+// pointing at an untouched, safely-readable tree.
+type ConcurrentTrie[V any] struct {
+	mu   sync.RWMutex
+	root *Node[V]
+}
+
+// CreateConcurrentTrie creates an empty, concurrency-safe trie.
+func CreateConcurrentTrie[V any]() *ConcurrentTrie[V] {
+	return &ConcurrentTrie[V]{root: &Node[V]{}}
+}
+
+// Insert adds data indexed by prefix to the trie.
+func (ct *ConcurrentTrie[V]) Insert(data V, prefix []rune) error {
+	if len(prefix) == 0 {
+		return errEmptyPrefix
+	}
+
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+
+	ct.root = ct.root.cowInsert(data, prefix)
+	return nil
+}
+
+// ErrAlreadyExists is returned by InsertIfAbsent when prefix is already in
+// the trie.
+var ErrAlreadyExists = errors.New("prefix already exists")
+
+// InsertIfAbsent adds data indexed by prefix to the trie, atomically with
+// respect to other writers: the check and the insert happen under the same
+// lock, so two concurrent callers racing on the same prefix can never both
+// succeed. Returns ErrAlreadyExists if prefix is already in the trie.
+func (ct *ConcurrentTrie[V]) InsertIfAbsent(data V, prefix []rune) error {
+	if len(prefix) == 0 {
+		return errEmptyPrefix
+	}
+
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+
+	if _, err := (&Trie[V]{root: ct.root}).searchNode(prefix); err == nil {
+		return ErrAlreadyExists
+	}
+
+	ct.root = ct.root.cowInsert(data, prefix)
+	return nil
+}
+
+// Delete removes prefix from the trie.
+// Returns an error if prefix doesn't exist.
+func (ct *ConcurrentTrie[V]) Delete(prefix []rune) error {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+
+	newRoot, err := cowDelete(ct.root, prefix)
+	if err != nil {
+		return err
+	}
+
+	ct.root = newRoot
+	return nil
+}
+
+// Update overwrites the data stored at prefix.
+// Returns an error if prefix doesn't exist.
+func (ct *ConcurrentTrie[V]) Update(prefix []rune, data V) error {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+
+	if _, err := (&Trie[V]{root: ct.root}).searchNode(prefix); err != nil {
+		return err
+	}
+
+	ct.root = ct.root.cowInsert(data, prefix)
+	return nil
+}
+
+// Search looks for the node indexed by prefix.
+func (ct *ConcurrentTrie[V]) Search(prefix []rune) (V, error) {
+	ct.mu.RLock()
+	defer ct.mu.RUnlock()
+
+	return (&Trie[V]{root: ct.root}).Search(prefix)
+}
+
+// Visit walks every terminal descendant reachable through prefix. See
+// Trie.Visit.
+func (ct *ConcurrentTrie[V]) Visit(prefix []rune, visitor func(key []rune, data V) error) error {
+	ct.mu.RLock()
+	defer ct.mu.RUnlock()
+
+	return (&Trie[V]{root: ct.root}).Visit(prefix, visitor)
+}
+
+// GetByPrefix returns the unique key/data pair reachable through prefix. See
+// Trie.GetByPrefix.
+func (ct *ConcurrentTrie[V]) GetByPrefix(prefix []rune) ([]rune, V, error) {
+	ct.mu.RLock()
+	defer ct.mu.RUnlock()
+
+	return (&Trie[V]{root: ct.root}).GetByPrefix(prefix)
+}
+
+// Snapshot returns an immutable read-only view of the trie as of now.
+// Writers may keep mutating ct afterwards: copy-on-write guarantees the
+// nodes reachable from the returned Trie are never touched again. Only
+// call read methods (Search, Visit, VisitSubtree, GetByPrefix, PrintTrie)
+// on the result.
+func (ct *ConcurrentTrie[V]) Snapshot() *Trie[V] {
+	ct.mu.RLock()
+	defer ct.mu.RUnlock()
+
+	return &Trie[V]{root: ct.root}
+}
+
+// Txn groups a batch of writes that become the next snapshot atomically on
+// Commit, or have no effect at all on Abort. Only one Txn may be open on a
+// ConcurrentTrie at a time.
+type Txn[V any] struct {
+	ct   *ConcurrentTrie[V]
+	root *Node[V]
+}
+
+// Txn starts a new write transaction, blocking until any other writer or
+// open Txn is done.
+func (ct *ConcurrentTrie[V]) Txn() *Txn[V] {
+	ct.mu.Lock()
+	return &Txn[V]{ct: ct, root: ct.root}
+}
+
+// Insert stages an insert in the transaction.
+func (tx *Txn[V]) Insert(data V, prefix []rune) error {
+	if len(prefix) == 0 {
+		return errEmptyPrefix
+	}
+
+	tx.root = tx.root.cowInsert(data, prefix)
+	return nil
+}
+
+// Delete stages a delete in the transaction.
+// Returns an error if prefix doesn't exist.
+func (tx *Txn[V]) Delete(prefix []rune) error {
+	newRoot, err := cowDelete(tx.root, prefix)
+	if err != nil {
+		return err
+	}
+
+	tx.root = newRoot
+	return nil
+}
+
+// Commit makes the staged writes the trie's next snapshot and releases the
+// transaction's lock.
+func (tx *Txn[V]) Commit() {
+	tx.ct.root = tx.root
+	tx.ct.mu.Unlock()
+}
+
+// Abort discards the staged writes and releases the transaction's lock.
+func (tx *Txn[V]) Abort() {
+	tx.ct.mu.Unlock()
+}
+
+var errEmptyPrefix = errors.New("Can't insert node with empty prefix")
+
+// cloneNode makes a shallow copy of n: its own fields are copied, but
+// children still point at the original (unmodified) subtrees.
+func cloneNode[V any](n *Node[V]) *Node[V] {
+	children := make([]*Node[V], len(n.children))
+	copy(children, n.children)
+
+	return &Node[V]{
+		data:       n.data,
+		isTerminal: n.isTerminal,
+		prefix:     append([]rune{}, n.prefix...),
+		children:   children,
+	}
+}
+
+// newEdgeChain builds a brand new chain of nodes for prefix, chunked by
+// MaxPrefixPerNode, and returns its head so a caller can append it as a
+// child. Every node it creates is new, so no cloning is needed here.
+func newEdgeChain[V any](data V, prefix []rune) *Node[V] {
+	head := &Node[V]{}
+	cur := head
+
+	for len(prefix) > MaxPrefixPerNode {
+		cur.prefix = prefix[:MaxPrefixPerNode]
+		prefix = prefix[MaxPrefixPerNode:]
+		next := &Node[V]{}
+		cur.children = []*Node[V]{next}
+		cur = next
+	}
+
+	cur.prefix = prefix
+	cur.isTerminal = true
+	cur.data = data
+
+	return head
+}
+
+// cowInsert returns a new node equivalent to inserting data at prefix under
+// n, cloning only the nodes on the path it touches.
+func (n *Node[V]) cowInsert(data V, prefix []rune) *Node[V] {
+	cloned := cloneNode(n)
+
+	for i, child := range cloned.children {
+		common := commonPrefixLen(child.prefix, prefix)
+		if common == 0 {
+			continue
+		}
+
+		switch {
+		case common == len(child.prefix) && common == len(prefix):
+			newChild := cloneNode(child)
+			newChild.isTerminal = true
+			newChild.data = data
+			cloned.children[i] = newChild
+			return cloned
+		case common == len(child.prefix):
+			cloned.children[i] = child.cowInsert(data, prefix[common:])
+			return cloned
+		default:
+			cloned.children[i] = cowSplit(child, common, data, prefix)
+			return cloned
+		}
+	}
+
+	cloned.children = append(cloned.children, newEdgeChain[V](data, prefix))
+	return cloned
+}
+
+// cowSplit builds the replacement for child's slot when only part of its
+// edge matches prefix, without mutating child itself.
+func cowSplit[V any](child *Node[V], common int, data V, prefix []rune) *Node[V] {
+	mid := &Node[V]{prefix: append([]rune{}, child.prefix[:common]...)}
+
+	tail := cloneNode(child)
+	tail.prefix = append([]rune{}, child.prefix[common:]...)
+	mid.children = []*Node[V]{tail}
+
+	rest := prefix[common:]
+	if len(rest) == 0 {
+		mid.isTerminal = true
+		mid.data = data
+		return mid
+	}
+
+	mid.children = append(mid.children, newEdgeChain[V](data, rest))
+	return mid
+}
+
+// cowDelete returns a new root equivalent to deleting prefix from root,
+// cloning only the nodes on the path to prefix. Returns ErrNotExist if
+// prefix doesn't exist.
+func cowDelete[V any](root *Node[V], prefix []rune) (*Node[V], error) {
+	path := []*Node[V]{root}
+	n := root
+	remaining := prefix
+
+	for len(remaining) > 0 {
+		child := n.matchingChild(remaining)
+		if child == nil || !hasPrefix(remaining, child.prefix) {
+			return nil, ErrNotExist
+		}
+		remaining = remaining[len(child.prefix):]
+		n = child
+		path = append(path, n)
+	}
+
+	if !n.isTerminal {
+		return nil, ErrNotExist
+	}
+
+	cloned := make([]*Node[V], len(path))
+	for i := len(path) - 1; i >= 0; i-- {
+		cloned[i] = cloneNode(path[i])
+		if i < len(path)-1 {
+			for j, c := range cloned[i].children {
+				if c == path[i+1] {
+					cloned[i].children[j] = cloned[i+1]
+					break
+				}
+			}
+		}
+	}
+
+	var zero V
+	leaf := cloned[len(cloned)-1]
+	leaf.isTerminal = false
+	leaf.data = zero
+
+	for i := len(cloned) - 1; i > 0; i-- {
+		node := cloned[i]
+		parent := cloned[i-1]
+
+		if len(node.children) == 0 && !node.isTerminal {
+			parent.removeChild(node)
+			continue
+		}
+
+		if len(node.children) == 1 && !node.isTerminal {
+			only := node.children[0]
+			node.prefix = append(node.prefix, only.prefix...)
+			node.isTerminal = only.isTerminal
+			node.data = only.data
+			node.children = only.children
+		}
+
+		break
+	}
+
+	return cloned[0], nil
+}
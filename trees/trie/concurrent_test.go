@@ -0,0 +1,130 @@
+package trie
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConcurrentTrieInsertSearch(t *testing.T) {
+	ct := CreateConcurrentTrie[int]()
+	assert.NoError(t, ct.Insert(1, []rune("car")))
+	assert.NoError(t, ct.Insert(2, []rune("cart")))
+
+	ans, err := ct.Search([]rune("cart"))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, ans)
+}
+
+func TestConcurrentTrieInsertIfAbsent(t *testing.T) {
+	ct := CreateConcurrentTrie[int]()
+	assert.NoError(t, ct.InsertIfAbsent(1, []rune("car")))
+	assert.ErrorIs(t, ct.InsertIfAbsent(2, []rune("car")), ErrAlreadyExists)
+
+	ans, err := ct.Search([]rune("car"))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, ans)
+}
+
+func TestConcurrentTrieInsertIfAbsentRace(t *testing.T) {
+	ct := CreateConcurrentTrie[int]()
+
+	const racers = 50
+	var wg sync.WaitGroup
+	successes := make([]bool, racers)
+
+	for i := 0; i < racers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			successes[i] = ct.InsertIfAbsent(i, []rune("same-id")) == nil
+		}(i)
+	}
+	wg.Wait()
+
+	wins := 0
+	for _, ok := range successes {
+		if ok {
+			wins++
+		}
+	}
+	assert.Equal(t, 1, wins, "exactly one InsertIfAbsent should win the race")
+}
+
+func TestConcurrentTrieSnapshotIsolation(t *testing.T) {
+	ct := CreateConcurrentTrie[int]()
+	assert.NoError(t, ct.Insert(1, []rune("car")))
+
+	snap := ct.Snapshot()
+
+	assert.NoError(t, ct.Insert(2, []rune("cart")))
+	assert.NoError(t, ct.Delete([]rune("car")))
+
+	// The snapshot must still see the tree as it was when taken.
+	ans, err := snap.Search([]rune("car"))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, ans)
+
+	_, err = snap.Search([]rune("cart"))
+	assert.ErrorIs(t, err, ErrNotExist)
+
+	// The live trie reflects both writes.
+	ans, err = ct.Search([]rune("cart"))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, ans)
+
+	_, err = ct.Search([]rune("car"))
+	assert.ErrorIs(t, err, ErrNotExist)
+}
+
+func TestConcurrentTrieTxnCommit(t *testing.T) {
+	ct := CreateConcurrentTrie[int]()
+
+	tx := ct.Txn()
+	assert.NoError(t, tx.Insert(1, []rune("car")))
+	assert.NoError(t, tx.Insert(2, []rune("cart")))
+	tx.Commit()
+
+	ans, err := ct.Search([]rune("cart"))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, ans)
+}
+
+func TestConcurrentTrieTxnAbort(t *testing.T) {
+	ct := CreateConcurrentTrie[int]()
+	assert.NoError(t, ct.Insert(1, []rune("car")))
+
+	tx := ct.Txn()
+	assert.NoError(t, tx.Insert(2, []rune("cart")))
+	tx.Abort()
+
+	_, err := ct.Search([]rune("cart"))
+	assert.ErrorIs(t, err, ErrNotExist)
+
+	ans, err := ct.Search([]rune("car"))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, ans)
+}
+
+func TestConcurrentTrieParallelReadsAndWrites(t *testing.T) {
+	ct := CreateConcurrentTrie[int]()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_ = ct.Insert(i, []rune("key"+string(rune('a'+i%26))))
+		}(i)
+	}
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			snap := ct.Snapshot()
+			_ = snap.Visit(nil, func(key []rune, data int) error { return nil })
+		}()
+	}
+	wg.Wait()
+}
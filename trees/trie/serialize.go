@@ -0,0 +1,268 @@
+package trie
+
+import (
+	"bufio"
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"io"
+)
+
+// MarshalBinary and friends below are package-level functions rather than
+// methods on Trie, because Go generics don't let a method narrow its
+// receiver's type parameter: Trie[V] has to work for every V, but encoding
+// a node's data only makes sense when V knows how to marshal itself.
+//
+// The wire format is a varint node count followed by the tree in preorder;
+// each node writes its prefix length, prefix runes, an isTerminal flag,
+// its data (only when terminal), and its child count.
+
+// MarshalBinary encodes t into a compact binary representation.
+func MarshalBinary[V encoding.BinaryMarshaler](t *Trie[V]) ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := WriteTo(t, &buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a Trie previously produced by MarshalBinary.
+// newValue must return a fresh, zero V to unmarshal each terminal node's
+// data into.
+func UnmarshalBinary[V encoding.BinaryUnmarshaler](data []byte, newValue func() V) (*Trie[V], error) {
+	t, _, err := ReadFrom(bytes.NewReader(data), newValue)
+	return t, err
+}
+
+// WriteTo streams t's binary representation to w, returning the number of
+// bytes written.
+func WriteTo[V encoding.BinaryMarshaler](t *Trie[V], w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+
+	if err := writeUvarint(cw, uint64(countNodes(t.root))); err != nil {
+		return cw.n, err
+	}
+
+	if err := writeNode(cw, t.root); err != nil {
+		return cw.n, err
+	}
+
+	return cw.n, nil
+}
+
+// ReadFrom streams a Trie previously written by WriteTo from r, returning
+// the number of bytes read from r. r is read through a buffer, so that
+// count, and what is read from r, can run past the end of the encoded trie.
+// newValue must return a fresh, zero V to unmarshal each terminal node's
+// data into.
+func ReadFrom[V encoding.BinaryUnmarshaler](r io.Reader, newValue func() V) (*Trie[V], int64, error) {
+	cr := &countingReader{r: r}
+	br := bufio.NewReader(cr)
+
+	if _, err := binary.ReadUvarint(br); err != nil {
+		return nil, cr.n, err
+	}
+
+	root, err := readNode(br, newValue)
+	if err != nil {
+		return nil, cr.n, err
+	}
+
+	return &Trie[V]{root: root}, cr.n, nil
+}
+
+func countNodes[V any](n *Node[V]) int {
+	count := 1
+	for _, c := range n.children {
+		count += countNodes(c)
+	}
+	return count
+}
+
+func writeNode[V encoding.BinaryMarshaler](w io.Writer, n *Node[V]) error {
+	if err := writeUvarint(w, uint64(len(n.prefix))); err != nil {
+		return err
+	}
+
+	for _, r := range n.prefix {
+		if err := writeUvarint(w, uint64(r)); err != nil {
+			return err
+		}
+	}
+
+	terminalByte := byte(0)
+	if n.isTerminal {
+		terminalByte = 1
+	}
+	if _, err := w.Write([]byte{terminalByte}); err != nil {
+		return err
+	}
+
+	if n.isTerminal {
+		dataBytes, err := n.data.MarshalBinary()
+		if err != nil {
+			return err
+		}
+
+		if err := writeUvarint(w, uint64(len(dataBytes))); err != nil {
+			return err
+		}
+
+		if _, err := w.Write(dataBytes); err != nil {
+			return err
+		}
+	}
+
+	if err := writeUvarint(w, uint64(len(n.children))); err != nil {
+		return err
+	}
+
+	for _, c := range n.children {
+		if err := writeNode(w, c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func readNode[V encoding.BinaryUnmarshaler](r *bufio.Reader, newValue func() V) (*Node[V], error) {
+	prefixLen, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := make([]rune, prefixLen)
+	for i := range prefix {
+		v, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, err
+		}
+		prefix[i] = rune(v)
+	}
+
+	terminalByte, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	isTerminal := terminalByte == 1
+
+	var data V
+	if isTerminal {
+		dataLen, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, err
+		}
+
+		dataBytes := make([]byte, dataLen)
+		if _, err := io.ReadFull(r, dataBytes); err != nil {
+			return nil, err
+		}
+
+		data = newValue()
+		if err := data.UnmarshalBinary(dataBytes); err != nil {
+			return nil, err
+		}
+	}
+
+	childCount, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+
+	children := make([]*Node[V], 0, childCount)
+	for i := uint64(0); i < childCount; i++ {
+		child, err := readNode(r, newValue)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, child)
+	}
+
+	return &Node[V]{data: data, isTerminal: isTerminal, prefix: prefix, children: children}, nil
+}
+
+func writeUvarint(w io.Writer, v uint64) error {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], v)
+	_, err := w.Write(buf[:n])
+	return err
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	written, err := cw.w.Write(p)
+	cw.n += int64(written)
+	return written, err
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	read, err := cr.r.Read(p)
+	cr.n += int64(read)
+	return read, err
+}
+
+// Iterator walks a Trie's keys in lexicographic order without
+// materializing them all upfront, so callers can paginate.
+type Iterator[V any] struct {
+	stack []*iterFrame[V]
+}
+
+type iterFrame[V any] struct {
+	node     *Node[V]
+	key      []rune
+	visited  bool
+	children []*Node[V]
+	childIdx int
+}
+
+// Iterator returns an iterator over every terminal descendant reachable
+// through startPrefix, in lexicographic order. A startPrefix that doesn't
+// exist in the trie yields an iterator with no entries.
+func (t *Trie[V]) Iterator(startPrefix []rune) *Iterator[V] {
+	n, base, err := t.nodeForPrefix(startPrefix)
+	if err != nil {
+		return &Iterator[V]{}
+	}
+
+	return &Iterator[V]{stack: []*iterFrame[V]{{node: n, key: base}}}
+}
+
+// Next returns the next key/data pair in order. ok is false once the
+// iterator is exhausted.
+func (it *Iterator[V]) Next() ([]rune, V, bool) {
+	var zero V
+
+	for len(it.stack) > 0 {
+		top := it.stack[len(it.stack)-1]
+
+		if !top.visited {
+			top.visited = true
+			top.children = top.node.sortedChildren()
+			if top.node.isTerminal {
+				return append([]rune{}, top.key...), top.node.data, true
+			}
+		}
+
+		if top.childIdx < len(top.children) {
+			child := top.children[top.childIdx]
+			top.childIdx++
+			childKey := append(append([]rune{}, top.key...), child.prefix...)
+			it.stack = append(it.stack, &iterFrame[V]{node: child, key: childKey})
+			continue
+		}
+
+		it.stack = it.stack[:len(it.stack)-1]
+	}
+
+	return nil, zero, false
+}
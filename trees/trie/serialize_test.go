@@ -0,0 +1,120 @@
+package trie
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type encInt int
+
+func (e encInt) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, uint64(e))
+	return buf, nil
+}
+
+func (e *encInt) UnmarshalBinary(data []byte) error {
+	*e = encInt(binary.BigEndian.Uint64(data))
+	return nil
+}
+
+func newEncInt() *encInt {
+	return new(encInt)
+}
+
+func buildEncTrie(t *testing.T) *Trie[*encInt] {
+	t.Helper()
+	tt := CreateTrie[*encInt]()
+	entries := map[string]int{"car": 1, "cart": 2, "carton": 3, "dog": 4}
+	for k, v := range entries {
+		e := encInt(v)
+		assert.NoError(t, tt.Insert(&e, []rune(k)))
+	}
+	return tt
+}
+
+func TestMarshalUnmarshalBinaryRoundTrip(t *testing.T) {
+	tt := buildEncTrie(t)
+
+	data, err := MarshalBinary[*encInt](tt)
+	assert.NoError(t, err)
+
+	got, err := UnmarshalBinary(data, newEncInt)
+	assert.NoError(t, err)
+
+	for _, key := range []string{"car", "cart", "carton", "dog"} {
+		want, err := tt.Search([]rune(key))
+		assert.NoError(t, err)
+
+		ans, err := got.Search([]rune(key))
+		assert.NoError(t, err)
+		assert.Equal(t, *want, *ans)
+	}
+}
+
+func TestWriteToReadFromRoundTrip(t *testing.T) {
+	tt := buildEncTrie(t)
+
+	var buf bytes.Buffer
+	n, err := WriteTo[*encInt](tt, &buf)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(buf.Len()), n)
+
+	got, _, err := ReadFrom(&buf, newEncInt)
+	assert.NoError(t, err)
+
+	ans, err := got.Search([]rune("carton"))
+	assert.NoError(t, err)
+	assert.Equal(t, encInt(3), *ans)
+}
+
+func TestIteratorPagination(t *testing.T) {
+	tt := CreateTrie[int]()
+	entries := []string{"car", "cart", "carton", "cat", "dog"}
+	for i, e := range entries {
+		assert.NoError(t, tt.Insert(i, []rune(e)))
+	}
+
+	it := tt.Iterator(nil)
+	var got []string
+	for {
+		key, _, ok := it.Next()
+		if !ok {
+			break
+		}
+		got = append(got, string(key))
+	}
+
+	assert.Equal(t, []string{"car", "cart", "carton", "cat", "dog"}, got)
+}
+
+func TestIteratorFromPrefix(t *testing.T) {
+	tt := CreateTrie[int]()
+	for i, e := range []string{"car", "cart", "cat", "dog"} {
+		assert.NoError(t, tt.Insert(i, []rune(e)))
+	}
+
+	it := tt.Iterator([]rune("car"))
+	var got []string
+	for {
+		key, _, ok := it.Next()
+		if !ok {
+			break
+		}
+		got = append(got, string(key))
+	}
+
+	assert.Equal(t, []string{"car", "cart"}, got)
+}
+
+func TestIteratorUnknownPrefixIsEmpty(t *testing.T) {
+	tt := CreateTrie[int]()
+	assert.NoError(t, tt.Insert(1, []rune("car")))
+
+	it := tt.Iterator([]rune("zzz"))
+	_, _, ok := it.Next()
+	assert.False(t, ok)
+}
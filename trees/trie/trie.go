@@ -3,41 +3,62 @@ package trie
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 )
 
-// Data is the data type the trie holds
-type Data int
+// MaxPrefixPerNode caps how many runes a single edge can carry before it
+// gets chunked into a chain of intermediate nodes. Lowering it trades node
+// count for shallower comparisons per node; raising it does the opposite.
+// The cap applies when edges are added on insert; Delete's merge of a node
+// with its only remaining child does not re-chunk, so a merged edge can be
+// longer than MaxPrefixPerNode.
+var MaxPrefixPerNode = 32
+
+// ErrNotExist is returned when a prefix isn't found in the trie.
+var ErrNotExist = errors.New("prefix does not exist")
+
+// ErrAmbiguousPrefix is returned by GetByPrefix when more than one key
+// matches the given prefix. The prefix is kept in its Prefix field.
+type ErrAmbiguousPrefix struct {
+	Prefix []rune
+}
+
+func (e *ErrAmbiguousPrefix) Error() string {
+	return fmt.Sprintf("prefix %q matches more than one key", string(e.Prefix))
+}
 
-// Trie is the root of the tree.
-// It doesn't have any data or prefixes.
-type Trie struct {
-	root *Node
+// Trie is the root of the tree. It doesn't have any data or prefix.
+// V is the type of value stored at each key.
+type Trie[V any] struct {
+	root *Node[V]
 }
 
-// Node is a node of the Trie.
-type Node struct {
-	data       Data
+// Node is a node of the Trie. Unlike a plain trie, a Node here can carry a
+// run of several runes in prefix (path compression), so a chain of nodes
+// with no branching collapses into a single edge.
+type Node[V any] struct {
+	data       V
 	isTerminal bool
-	prefix     rune
-	children   []*Node
+	prefix     []rune
+	children   []*Node[V]
 }
 
 // CreateTrie creates an empty Trie tree and returns it.
-func CreateTrie() *Trie {
-	return &Trie{&Node{}}
+func CreateTrie[V any]() *Trie[V] {
+	return &Trie[V]{&Node[V]{}}
 }
 
-// CreateNode creates a new node.
+// CreateNode creates a new node holding prefix.
 // Returns a pointer to that node.
-func CreateNode(data Data, isTerminal bool, prefix rune) *Node {
-	return &Node{data, isTerminal, prefix, nil}
+func CreateNode[V any](data V, isTerminal bool, prefix []rune) *Node[V] {
+	return &Node[V]{data, isTerminal, prefix, nil}
 }
 
-// Insert appends a node (n) containing data and prefix to the trie.
-// Returns an error if the node already exists.
-func (t *Trie) Insert(data Data, prefix []rune) error {
-
+// Insert adds data indexed by prefix to the trie.
+// If prefix already exists, it is made (or kept) terminal and its data is
+// overwritten.
+func (t *Trie[V]) Insert(data V, prefix []rune) error {
 	if len(prefix) == 0 {
 		return errors.New("Can't insert node with empty prefix")
 	}
@@ -46,71 +67,104 @@ func (t *Trie) Insert(data Data, prefix []rune) error {
 		return errors.New("Can't insert on nil trie")
 	}
 
-	n := t.root
-	lastChar := 0
-	// Move to the last existing node
-	for i, c := range prefix {
-		aux := n.hasChildWithPrefix(c)
-		if aux == nil { // doesn't have child, insert it
-			lastChar = i
-			break // Stops when child with prefix char doesn't exist
+	return t.root.insert(data, prefix)
+}
+
+// insert walks the edges of n looking for where prefix belongs, splitting
+// an edge if only part of it matches.
+func (n *Node[V]) insert(data V, prefix []rune) error {
+	for _, child := range n.children {
+		common := commonPrefixLen(child.prefix, prefix)
+		if common == 0 {
+			continue
+		}
+
+		switch {
+		case common == len(child.prefix) && common == len(prefix):
+			// Edge matches prefix exactly.
+			child.isTerminal = true
+			child.data = data
+			return nil
+		case common == len(child.prefix):
+			// Whole edge matched, keep walking down with the rest.
+			return child.insert(data, prefix[common:])
+		default:
+			// Only part of the edge matched: split it.
+			return n.splitChild(child, common, data, prefix)
 		}
-		n = aux // has child, go down in tree
 	}
 
-	remainingPrefix := []rune(prefix)[lastChar:]
-	return n.createSubTree(data, remainingPrefix)
+	return n.addChild(data, prefix)
 }
 
-// createSubTree inserts the rest of a prefix beginning
-// in the Node n.
-func (n *Node) createSubTree(data Data, prefix []rune) error {
-	var newNode *Node
-	var newNodeDad = n
+// splitChild breaks child's edge at position common, inserting a new
+// intermediate node that carries the shared prefix. child keeps its
+// remaining tail, and the rest of prefix (if any) becomes a sibling of it.
+func (n *Node[V]) splitChild(child *Node[V], common int, data V, prefix []rune) error {
+	var zero V
+	mid := CreateNode(zero, false, child.prefix[:common])
+	child.prefix = child.prefix[common:]
+	mid.children = []*Node[V]{child}
+
+	for i, c := range n.children {
+		if c == child {
+			n.children[i] = mid
+			break
+		}
+	}
 
-	// Node already exists, make terminal
-	if len(prefix) == 1 && n.prefix == prefix[0] {
-		n.isTerminal = true
-		n.data = data
+	rest := prefix[common:]
+	if len(rest) == 0 {
+		mid.isTerminal = true
+		mid.data = data
 		return nil
 	}
 
-	for _, c := range prefix {
-		newNode = CreateNode(-1, false, c)
-		(*newNodeDad).children = append((*newNodeDad).children, newNode)
-		newNodeDad = newNode
+	return mid.addChild(data, rest)
+}
+
+// addChild appends a brand new edge to n for prefix, chunking it into
+// MaxPrefixPerNode-sized pieces when it's longer than that.
+func (n *Node[V]) addChild(data V, prefix []rune) error {
+	var zero V
+	cur := n
+	for len(prefix) > MaxPrefixPerNode {
+		next := CreateNode(zero, false, prefix[:MaxPrefixPerNode])
+		cur.children = append(cur.children, next)
+		cur = next
+		prefix = prefix[MaxPrefixPerNode:]
 	}
 
-	// Insert data in last node
-	newNode.isTerminal = true
-	newNode.data = data
+	leaf := CreateNode(data, true, prefix)
+	cur.children = append(cur.children, leaf)
 
 	return nil
 }
 
-// hasChildWithPrefix returns a *Node containing
-// the child of n that has a prefix of c.
-// Returns false otherwise.
-func (n *Node) hasChildWithPrefix(c rune) *Node {
-
-	if n.children == nil {
-		return nil
+// commonPrefixLen returns the length of the longest common prefix of a and b.
+func commonPrefixLen(a, b []rune) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
 	}
+	return i
+}
 
+// matchingChild returns the child of n whose edge shares a common prefix
+// with remaining, or nil if none does.
+func (n *Node[V]) matchingChild(remaining []rune) *Node[V] {
 	for _, child := range n.children {
-		if child.prefix == c {
+		if commonPrefixLen(child.prefix, remaining) > 0 {
 			return child
 		}
 	}
-
 	return nil
 }
 
 // Delete searches for a prefix in the Trie.
-// Removes the node and rearranges the tree if prefix exists.
+// Removes the node and merges the tree if prefix exists.
 // Returns an error if prefix doesn't exist.
-func (t *Trie) Delete(prefix []rune) error {
-
+func (t *Trie[V]) Delete(prefix []rune) error {
 	if t == nil {
 		return errors.New("Can't delete in nil trie")
 	}
@@ -119,52 +173,91 @@ func (t *Trie) Delete(prefix []rune) error {
 		return errors.New("Can't delete nil prefix")
 	}
 
-	lookup := t.root
-	lastUseful := t.root
-	deleteIDX := -1
+	path, err := t.pathTo(prefix)
+	if err != nil {
+		return err
+	}
 
-	// Get last useful node (which we can't delete)
-	for i, c := range prefix {
-		// We can't delete lookup if it has more than 1 child
-		// or if it is terminal for another prefix
-		if len(lookup.children) > 1 ||
-			(lookup.isTerminal && i != len(prefix)-1) {
-			lastUseful = lookup
+	var zero V
+	leaf := path[len(path)-1]
+	leaf.isTerminal = false
+	leaf.data = zero
+
+	// Walk back up, merging or pruning nodes that are no longer useful.
+	for i := len(path) - 1; i > 0; i-- {
+		node := path[i]
+		parent := path[i-1]
+
+		if len(node.children) == 0 && !node.isTerminal {
+			parent.removeChild(node)
+			continue
 		}
-		// Go down in tree
-		for j, n := range lookup.children {
-			if n.prefix == c {
-				if lastUseful == lookup {
-					deleteIDX = j
-				}
-				lookup = n
-				break
-			}
-			if j == len(lookup.children)-1 {
-				return errors.New("Didn't find prefix")
-			}
+
+		if len(node.children) == 1 && !node.isTerminal {
+			only := node.children[0]
+			node.prefix = append(node.prefix, only.prefix...)
+			node.isTerminal = only.isTerminal
+			node.data = only.data
+			node.children = only.children
 		}
+
+		break
 	}
 
-	if len(lookup.children) > 0 {
-		lookup.isTerminal = false
-		lookup.data = -1
-	} else {
-		// Remove subtree below lastUseful at index deleteIDX
-		lastUseful.children[deleteIDX] =
-			lastUseful.children[len(lastUseful.children)-1]
+	return nil
+}
 
-		(*lastUseful).children =
-			(*lastUseful).children[:len((*lastUseful).children)-1]
+// pathTo returns the chain of nodes from the root (inclusive) down to the
+// terminal node for prefix, or an error if prefix doesn't exist.
+func (t *Trie[V]) pathTo(prefix []rune) ([]*Node[V], error) {
+	path := []*Node[V]{t.root}
+	n := t.root
+	remaining := prefix
+
+	for len(remaining) > 0 {
+		child := n.matchingChild(remaining)
+		if child == nil || !hasPrefix(remaining, child.prefix) {
+			return nil, ErrNotExist
+		}
+		remaining = remaining[len(child.prefix):]
+		n = child
+		path = append(path, n)
 	}
 
-	return nil
+	if !n.isTerminal {
+		return nil, ErrNotExist
+	}
+
+	return path, nil
+}
+
+// hasPrefix reports whether s begins with prefix.
+func hasPrefix(s, prefix []rune) bool {
+	if len(prefix) > len(s) {
+		return false
+	}
+	for i, r := range prefix {
+		if s[i] != r {
+			return false
+		}
+	}
+	return true
+}
+
+// removeChild removes child from n's children, if present.
+func (n *Node[V]) removeChild(child *Node[V]) {
+	for i, c := range n.children {
+		if c == child {
+			n.children = append(n.children[:i], n.children[i+1:]...)
+			return
+		}
+	}
 }
 
 // Update searches for a prefix in the Trie.
 // Updates the node if prefix exists.
 // Returns an error if prefix doesn't exist.
-func (t *Trie) Update(prefix []rune, data Data) error {
+func (t *Trie[V]) Update(prefix []rune, data V) error {
 	n, err := t.searchNode(prefix)
 
 	if n != nil {
@@ -176,15 +269,17 @@ func (t *Trie) Update(prefix []rune, data Data) error {
 }
 
 // Search looks for the node indexed by prefix.
-// Returns a string containing the data if prefix exists.
-// Returns an empty string and error if prefix doesn't exist.
-func (t *Trie) Search(prefix []rune) (Data, error) {
+// Returns the data if prefix exists.
+// Returns a zero value and error if prefix doesn't exist.
+func (t *Trie[V]) Search(prefix []rune) (V, error) {
+	var zero V
+
 	if t == nil {
-		return 0, errors.New("Can't search in nil trie")
+		return zero, errors.New("Can't search in nil trie")
 	}
 
 	if prefix == nil {
-		return 0, errors.New("Can't search nil prefix")
+		return zero, errors.New("Can't search nil prefix")
 	}
 
 	n, err := t.searchNode(prefix)
@@ -193,56 +288,171 @@ func (t *Trie) Search(prefix []rune) (Data, error) {
 		return n.data, nil
 	}
 
-	return 0, err
+	return zero, err
 }
 
 // searchNode returns the node containing the data for prefix.
 // Returns an error if the prefix doesn't exist in the tree.
-func (t *Trie) searchNode(prefix []rune) (*Node, error) {
-	// Lookup node starts at root
-	lookup := t.root
-
-	for _, c := range prefix {
-		for i, n := range lookup.children {
-			if n.prefix == c { // found prefix, update lookup (go down)
-				lookup = n
-				break
-			}
-			if i == len(lookup.children)-1 { // at the last child of lookup
-				return nil, errors.New("Didn't find prefix")
-			}
+func (t *Trie[V]) searchNode(prefix []rune) (*Node[V], error) {
+	n := t.root
+	remaining := prefix
+
+	for len(remaining) > 0 {
+		child := n.matchingChild(remaining)
+		if child == nil || !hasPrefix(remaining, child.prefix) {
+			return nil, ErrNotExist
+		}
+		remaining = remaining[len(child.prefix):]
+		n = child
+	}
+
+	if n.isTerminal {
+		return n, nil
+	}
+
+	return nil, ErrNotExist
+}
+
+// VisitSubtree walks every terminal descendant reachable through prefix in
+// lexicographic order, calling visitor with the descendant's full
+// reconstructed key and its data. It stops and returns the first non-nil
+// error a visitor call produces. Returns ErrNotExist if prefix isn't a path
+// in the trie at all (even a non-terminal one).
+func (t *Trie[V]) VisitSubtree(prefix []rune, visitor func(key []rune, data V) error) error {
+	n, base, err := t.nodeForPrefix(prefix)
+	if err != nil {
+		return err
+	}
+
+	return n.visit(base, visitor)
+}
+
+// Visit walks every terminal descendant reachable through prefix, in
+// lexicographic order. It's a convenience alias for VisitSubtree, kept
+// separate so call sites can express "walk from here down" without
+// mentioning subtrees.
+func (t *Trie[V]) Visit(prefix []rune, visitor func(key []rune, data V) error) error {
+	return t.VisitSubtree(prefix, visitor)
+}
+
+// GetByPrefix returns the unique key/data pair reachable through prefix.
+// It returns ErrNotExist if no key has prefix, and an *ErrAmbiguousPrefix
+// if more than one does.
+func (t *Trie[V]) GetByPrefix(prefix []rune) ([]rune, V, error) {
+	errStopAmbiguous := errors.New("ambiguous prefix: stop")
+
+	var key []rune
+	var data V
+	count := 0
+
+	err := t.VisitSubtree(prefix, func(k []rune, d V) error {
+		count++
+		if count > 1 {
+			return errStopAmbiguous
 		}
+		key = append([]rune{}, k...)
+		data = d
+		return nil
+	})
+
+	var zero V
+	if err != nil && !errors.Is(err, errStopAmbiguous) {
+		return nil, zero, err
+	}
+
+	if count == 0 {
+		return nil, zero, ErrNotExist
 	}
 
-	if lookup.isTerminal {
-		return lookup, nil
+	if count > 1 {
+		return nil, zero, &ErrAmbiguousPrefix{Prefix: prefix}
 	}
 
-	return nil, errors.New("Didn't find prefix")
+	return key, data, nil
+}
+
+// nodeForPrefix walks the compressed edges matching prefix and returns the
+// node at the end of that path along with the full key reconstructed up to
+// (and including) that node. Returns ErrNotExist if prefix isn't a path in
+// the trie.
+func (t *Trie[V]) nodeForPrefix(prefix []rune) (*Node[V], []rune, error) {
+	n := t.root
+	remaining := prefix
+	base := []rune{}
+
+	for len(remaining) > 0 {
+		child := n.matchingChild(remaining)
+		if child == nil {
+			return nil, nil, ErrNotExist
+		}
+
+		common := commonPrefixLen(child.prefix, remaining)
+		if common < len(remaining) && common < len(child.prefix) {
+			return nil, nil, ErrNotExist
+		}
+
+		base = append(base, child.prefix...)
+		n = child
+		remaining = remaining[common:]
+	}
+
+	return n, base, nil
+}
+
+// visit recursively walks n's subtree, calling visitor on every terminal
+// node with its full reconstructed key, descending into children in
+// lexicographic order of their edge labels.
+func (n *Node[V]) visit(key []rune, visitor func(key []rune, data V) error) error {
+	if n.isTerminal {
+		if err := visitor(append([]rune{}, key...), n.data); err != nil {
+			return err
+		}
+	}
+
+	for _, c := range n.sortedChildren() {
+		childKey := append(append([]rune{}, key...), c.prefix...)
+		if err := c.visit(childKey, visitor); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sortedChildren returns a copy of n.children ordered lexicographically by
+// edge label, so descents produce keys in lexicographic order. It copies
+// rather than sorting in place because unrelated nodes, such as those
+// shared with a ConcurrentTrie snapshot, must never be mutated.
+func (n *Node[V]) sortedChildren() []*Node[V] {
+	children := append([]*Node[V]{}, n.children...)
+	sort.Slice(children, func(i, j int) bool {
+		return string(children[i].prefix) < string(children[j].prefix)
+	})
+	return children
 }
 
 // PrintTrie prints trie showing parent-child relationships
-func (t *Trie) PrintTrie() error {
+func (t *Trie[V]) PrintTrie() error {
 	if t.root.children == nil {
 		return nil
 	}
 
 	for _, n := range t.root.children {
-		fmt.Printf("%s: %d\n", string(n.prefix), int(n.data))
+		fmt.Printf("%s: %v\n", string(n.prefix), n.data)
 		n.printSubTree(1)
 	}
 
 	return nil
 }
 
-func (n *Node) printSubTree(tabs int) error {
+func (n *Node[V]) printSubTree(tabs int) error {
 	if n.children == nil {
 		return nil
 	}
 
 	for _, aux := range n.children {
 		fmt.Printf(strings.Repeat(" ", tabs))
-		fmt.Printf("%s: %d\n", string(aux.prefix), int(aux.data))
+		fmt.Printf("%s: %v\n", string(aux.prefix), aux.data)
 		aux.printSubTree(tabs + 1)
 	}
 
@@ -1,12 +1,13 @@
 package trie
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
-var trie = CreateTrie()
+var trie = CreateTrie[int]()
 var insertEntries = []string{
 	"and",
 	"a",
@@ -32,7 +33,7 @@ var updateEntries = []string{
 
 func TestInsert(t *testing.T) {
 	for i, entry := range insertEntries {
-		trie.Insert(Data(i), []rune(entry))
+		trie.Insert(int(i), []rune(entry))
 	}
 	trie.PrintTrie()
 }
@@ -40,22 +41,158 @@ func TestInsert(t *testing.T) {
 func TestSearch(t *testing.T) {
 	for expected, lookup := range insertEntries {
 		ans, err := trie.Search([]rune(lookup))
-		assert.Equal(t, Data(expected), ans, "should be equal")
+		assert.Equal(t, int(expected), ans, "should be equal")
 		assert.Equal(t, nil, err, "should be equal")
 	}
 }
 
+func TestSearchNotExist(t *testing.T) {
+	_, err := trie.Search([]rune("nope"))
+	assert.ErrorIs(t, err, ErrNotExist)
+}
+
 func TestDelete(t *testing.T) {
 	for _, entry := range deleteEntries {
 		trie.Delete([]rune(entry))
 	}
 	trie.PrintTrie()
+
+	for _, entry := range deleteEntries {
+		_, err := trie.Search([]rune(entry))
+		assert.ErrorIs(t, err, ErrNotExist)
+	}
+
+	// siblings that shared a compressed edge with a deleted key must
+	// still resolve correctly.
+	ans, err := trie.Search([]rune("andromeda"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, int(4), ans)
 }
 
 func TestUpdate(t *testing.T) {
 	for _, entry := range updateEntries {
 		trie.Update([]rune(entry), 1234)
 		ans, _ := trie.Search([]rune(entry))
-		assert.Equal(t, Data(1234), ans, "should be equal")
+		assert.Equal(t, int(1234), ans, "should be equal")
 	}
 }
+
+func TestPathCompressionMergesOnDelete(t *testing.T) {
+	tt := CreateTrie[int]()
+	tt.Insert(1, []rune("water"))
+	tt.Insert(2, []rune("waterfall"))
+
+	assert.NoError(t, tt.Delete([]rune("water")))
+
+	ans, err := tt.Search([]rune("waterfall"))
+	assert.NoError(t, err)
+	assert.Equal(t, int(2), ans)
+
+	// "water" should no longer resolve on its own.
+	_, err = tt.Search([]rune("water"))
+	assert.ErrorIs(t, err, ErrNotExist)
+}
+
+func TestMaxPrefixPerNodeChunking(t *testing.T) {
+	old := MaxPrefixPerNode
+	MaxPrefixPerNode = 4
+	defer func() { MaxPrefixPerNode = old }()
+
+	tt := CreateTrie[int]()
+	long := "abcdefghijklmnop"
+	assert.NoError(t, tt.Insert(42, []rune(long)))
+
+	ans, err := tt.Search([]rune(long))
+	assert.NoError(t, err)
+	assert.Equal(t, int(42), ans)
+
+	// the chunking chain shouldn't resolve at an intermediate boundary.
+	_, err = tt.Search([]rune(long[:4]))
+	assert.ErrorIs(t, err, ErrNotExist)
+}
+
+func TestVisitSubtree(t *testing.T) {
+	tt := CreateTrie[int]()
+	entries := []string{"car", "cart", "carton", "cat", "dog"}
+	for i, e := range entries {
+		assert.NoError(t, tt.Insert(int(i), []rune(e)))
+	}
+
+	var got []string
+	err := tt.VisitSubtree([]rune("car"), func(key []rune, data int) error {
+		got = append(got, string(key))
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"car", "cart", "carton"}, got)
+}
+
+func TestVisitSubtreeHaltsOnError(t *testing.T) {
+	tt := CreateTrie[int]()
+	for i, e := range []string{"car", "cart", "carton"} {
+		assert.NoError(t, tt.Insert(int(i), []rune(e)))
+	}
+
+	errStop := errors.New("stop")
+	calls := 0
+	err := tt.Visit([]rune("car"), func(key []rune, data int) error {
+		calls++
+		return errStop
+	})
+	assert.ErrorIs(t, err, errStop)
+	assert.Equal(t, 1, calls)
+}
+
+func TestVisitSubtreeNotExist(t *testing.T) {
+	tt := CreateTrie[int]()
+	assert.NoError(t, tt.Insert(1, []rune("car")))
+
+	err := tt.VisitSubtree([]rune("dog"), func(key []rune, data int) error {
+		return nil
+	})
+	assert.ErrorIs(t, err, ErrNotExist)
+}
+
+func TestGetByPrefixUnique(t *testing.T) {
+	tt := CreateTrie[int]()
+	assert.NoError(t, tt.Insert(1, []rune("abcdef")))
+	assert.NoError(t, tt.Insert(2, []rune("xyz")))
+
+	key, data, err := tt.GetByPrefix([]rune("abc"))
+	assert.NoError(t, err)
+	assert.Equal(t, "abcdef", string(key))
+	assert.Equal(t, int(1), data)
+}
+
+func TestGetByPrefixAmbiguous(t *testing.T) {
+	tt := CreateTrie[int]()
+	assert.NoError(t, tt.Insert(1, []rune("abcdef")))
+	assert.NoError(t, tt.Insert(2, []rune("abcxyz")))
+
+	_, _, err := tt.GetByPrefix([]rune("abc"))
+	var ambiguous *ErrAmbiguousPrefix
+	assert.ErrorAs(t, err, &ambiguous)
+}
+
+func TestGetByPrefixNotExist(t *testing.T) {
+	tt := CreateTrie[int]()
+	assert.NoError(t, tt.Insert(1, []rune("abc")))
+
+	_, _, err := tt.GetByPrefix([]rune("zzz"))
+	assert.ErrorIs(t, err, ErrNotExist)
+}
+
+type container struct {
+	ID    string
+	Image string
+}
+
+func TestGenericStructValue(t *testing.T) {
+	tt := CreateTrie[*container]()
+	c := &container{ID: "abc123", Image: "alpine"}
+	assert.NoError(t, tt.Insert(c, []rune(c.ID)))
+
+	ans, err := tt.Search([]rune(c.ID))
+	assert.NoError(t, err)
+	assert.Same(t, c, ans)
+}
@@ -0,0 +1,98 @@
+// Package truncindex indexes full IDs so callers can look them up by a
+// short, unambiguous prefix -- the way container and object stores let
+// users type just the first few characters of an ID.
+package truncindex
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gmelodie/go-ds/trees/trie"
+)
+
+// ErrDuplicateID is returned by Add when id is already indexed.
+var ErrDuplicateID = errors.New("ID already exists")
+
+// ErrEmptyPrefix is returned when an empty prefix is used to add or look
+// up an ID.
+var ErrEmptyPrefix = errors.New("prefix can't be empty")
+
+// ErrNotExist is returned when no ID matches the given prefix.
+var ErrNotExist = errors.New("ID does not exist")
+
+// ErrAmbiguousPrefix is returned by Get when more than one ID matches
+// prefix.
+type ErrAmbiguousPrefix struct {
+	Prefix string
+}
+
+func (e *ErrAmbiguousPrefix) Error() string {
+	return fmt.Sprintf("multiple IDs found with prefix %q", e.Prefix)
+}
+
+// TruncIndex indexes full IDs on a Patricia trie, keyed by the ID itself.
+type TruncIndex struct {
+	trie *trie.ConcurrentTrie[struct{}]
+}
+
+// NewTruncIndex creates an empty TruncIndex.
+func NewTruncIndex() *TruncIndex {
+	return &TruncIndex{trie: trie.CreateConcurrentTrie[struct{}]()}
+}
+
+// Add indexes id. Returns ErrEmptyPrefix if id is empty, or ErrDuplicateID
+// if id is already indexed.
+func (ti *TruncIndex) Add(id string) error {
+	if id == "" {
+		return ErrEmptyPrefix
+	}
+
+	err := ti.trie.InsertIfAbsent(struct{}{}, []rune(id))
+	if errors.Is(err, trie.ErrAlreadyExists) {
+		return ErrDuplicateID
+	}
+
+	return err
+}
+
+// Delete removes id from the index. Returns ErrNotExist if id isn't
+// indexed.
+func (ti *TruncIndex) Delete(id string) error {
+	err := ti.trie.Delete([]rune(id))
+	if errors.Is(err, trie.ErrNotExist) {
+		return ErrNotExist
+	}
+
+	return err
+}
+
+// Get returns the full ID that uniquely completes prefix.
+// Returns ErrEmptyPrefix if prefix is empty, ErrNotExist if no ID has
+// prefix, and an *ErrAmbiguousPrefix if more than one does.
+func (ti *TruncIndex) Get(prefix string) (string, error) {
+	if prefix == "" {
+		return "", ErrEmptyPrefix
+	}
+
+	key, _, err := ti.trie.GetByPrefix([]rune(prefix))
+
+	var ambiguous *trie.ErrAmbiguousPrefix
+	switch {
+	case errors.Is(err, trie.ErrNotExist):
+		return "", ErrNotExist
+	case errors.As(err, &ambiguous):
+		return "", &ErrAmbiguousPrefix{Prefix: prefix}
+	case err != nil:
+		return "", err
+	}
+
+	return string(key), nil
+}
+
+// Iterate calls fn with every indexed ID, in lexicographic order.
+func (ti *TruncIndex) Iterate(fn func(id string)) {
+	_ = ti.trie.Visit(nil, func(key []rune, _ struct{}) error {
+		fn(string(key))
+		return nil
+	})
+}
@@ -0,0 +1,109 @@
+package truncindex
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// naiveIndex is a map[string]struct{} with a linear prefix scan, the
+// simplest possible alternative to TruncIndex. The benchmarks below keep
+// the trie implementation honest against it as it evolves.
+type naiveIndex map[string]struct{}
+
+func (ni naiveIndex) getByPrefix(prefix string) (string, error) {
+	var found string
+	count := 0
+
+	for id := range ni {
+		if strings.HasPrefix(id, prefix) {
+			count++
+			found = id
+			if count > 1 {
+				break
+			}
+		}
+	}
+
+	switch {
+	case count == 0:
+		return "", ErrNotExist
+	case count > 1:
+		return "", &ErrAmbiguousPrefix{Prefix: prefix}
+	}
+
+	return found, nil
+}
+
+// idsN returns n distinct, fixed-width hex IDs, the shape of a sha256
+// container ID. Each ID is the hash of its index rather than a
+// zero-padded index itself, so entropy is spread across the leading
+// bytes instead of all being concentrated in the last few digits: a
+// short prefix of one ID is then genuinely unlikely to match another,
+// which is what a realistic short-ID lookup looks like.
+func idsN(n int) []string {
+	ids := make([]string, n)
+	for i := range ids {
+		sum := sha256.Sum256([]byte(fmt.Sprintf("id-%d", i)))
+		ids[i] = fmt.Sprintf("%x", sum)
+	}
+	return ids
+}
+
+var benchSizes = []int{100, 1000, 10000}
+
+func BenchmarkInsertN(b *testing.B) {
+	for _, n := range benchSizes {
+		ids := idsN(n)
+
+		b.Run(fmt.Sprintf("trie/n=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				ti := NewTruncIndex()
+				for _, id := range ids {
+					ti.Add(id)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("map/n=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				ni := make(naiveIndex, n)
+				for _, id := range ids {
+					ni[id] = struct{}{}
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkGetByPrefixN(b *testing.B) {
+	for _, n := range benchSizes {
+		ids := idsN(n)
+		prefix := ids[n/2][:8]
+
+		b.Run(fmt.Sprintf("trie/n=%d", n), func(b *testing.B) {
+			ti := NewTruncIndex()
+			for _, id := range ids {
+				ti.Add(id)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				ti.Get(prefix)
+			}
+		})
+
+		b.Run(fmt.Sprintf("map/n=%d", n), func(b *testing.B) {
+			ni := make(naiveIndex, n)
+			for _, id := range ids {
+				ni[id] = struct{}{}
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				ni.getByPrefix(prefix)
+			}
+		})
+	}
+}
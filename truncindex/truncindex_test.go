@@ -0,0 +1,106 @@
+package truncindex
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddAndGet(t *testing.T) {
+	ti := NewTruncIndex()
+	assert.NoError(t, ti.Add("abcdef0123"))
+
+	id, err := ti.Get("abc")
+	assert.NoError(t, err)
+	assert.Equal(t, "abcdef0123", id)
+}
+
+func TestAddEmptyID(t *testing.T) {
+	ti := NewTruncIndex()
+	assert.ErrorIs(t, ti.Add(""), ErrEmptyPrefix)
+}
+
+func TestAddDuplicateID(t *testing.T) {
+	ti := NewTruncIndex()
+	assert.NoError(t, ti.Add("abcdef0123"))
+	assert.ErrorIs(t, ti.Add("abcdef0123"), ErrDuplicateID)
+}
+
+func TestAddDuplicateIDUnderRace(t *testing.T) {
+	ti := NewTruncIndex()
+
+	const racers = 50
+	var wg sync.WaitGroup
+	successes := make([]bool, racers)
+
+	for i := 0; i < racers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			successes[i] = ti.Add("same-id") == nil
+		}(i)
+	}
+	wg.Wait()
+
+	wins := 0
+	for _, ok := range successes {
+		if ok {
+			wins++
+		}
+	}
+	assert.Equal(t, 1, wins, "exactly one Add should win the race")
+}
+
+func TestGetEmptyPrefix(t *testing.T) {
+	ti := NewTruncIndex()
+	_, err := ti.Get("")
+	assert.ErrorIs(t, err, ErrEmptyPrefix)
+}
+
+func TestGetNotExist(t *testing.T) {
+	ti := NewTruncIndex()
+	assert.NoError(t, ti.Add("abcdef0123"))
+
+	_, err := ti.Get("zzz")
+	assert.ErrorIs(t, err, ErrNotExist)
+}
+
+func TestGetAmbiguousPrefix(t *testing.T) {
+	ti := NewTruncIndex()
+	assert.NoError(t, ti.Add("abcdef0123"))
+	assert.NoError(t, ti.Add("abcdef9999"))
+
+	_, err := ti.Get("abcdef")
+	var ambiguous *ErrAmbiguousPrefix
+	assert.ErrorAs(t, err, &ambiguous)
+}
+
+func TestDelete(t *testing.T) {
+	ti := NewTruncIndex()
+	assert.NoError(t, ti.Add("abcdef0123"))
+	assert.NoError(t, ti.Delete("abcdef0123"))
+
+	_, err := ti.Get("abc")
+	assert.ErrorIs(t, err, ErrNotExist)
+}
+
+func TestDeleteNotExist(t *testing.T) {
+	ti := NewTruncIndex()
+	assert.ErrorIs(t, ti.Delete("abcdef0123"), ErrNotExist)
+}
+
+func TestIterate(t *testing.T) {
+	ti := NewTruncIndex()
+	ids := []string{"aaa", "bbb", "ccc"}
+	for _, id := range ids {
+		assert.NoError(t, ti.Add(id))
+	}
+
+	var got []string
+	ti.Iterate(func(id string) {
+		got = append(got, id)
+	})
+
+	assert.Equal(t, ids, got)
+}